@@ -20,6 +20,34 @@ type Config struct {
 	SchemasDir string `yaml:"schemasDir"`
 	// OutputDir is the directory to write the generated provider code.
 	OutputDir string `yaml:"outputDir"`
+	// DefaultToStorageVersion makes the storage version of a multi-version CRD
+	// keep the resource name without the version suffix.
+	DefaultToStorageVersion bool `yaml:"defaultToStorageVersion"`
+	// Mode defines how the generated resources are managed: crossplane (default) or plain.
+	// It can be overridden per CRD in Resources.
+	Mode string `yaml:"mode"`
+	// IgnoreFields are JSON-pointer paths of the schema properties that are not generated, e.g. /spec/resourceRef.
+	// They are applied to every CRD in addition to the per-CRD IgnoreFields.
+	IgnoreFields []string `yaml:"ignoreFields"`
+	// IgnoreCrossplaneFields enables the preset of the fields managed by Crossplane.
+	// Defaults to true in the crossplane mode and false in the plain mode.
+	IgnoreCrossplaneFields *bool `yaml:"ignoreCrossplaneFields"`
+	// CompositionSelection keeps the Crossplane composition selection fields
+	// (compositionRef, compositionSelector, compositionUpdatePolicy, compositionRevisionRef and compositionRevisionSelector)
+	// as optional spec attributes. It can be overridden per CRD in Resources.
+	CompositionSelection bool `yaml:"compositionSelection"`
+	// ConnectionSecret makes Crossplane v1 claims and composite resources write the connection details
+	// to a secret that is read into the sensitive connection_details attribute.
+	// It can be overridden per CRD in Resources.
+	ConnectionSecret bool `yaml:"connectionSecret"`
+	// FieldManager is the server-side apply field manager that owns the fields set by the provider.
+	// Defaults to terraform-provider-<name>. It can be overridden with the field_manager provider argument.
+	FieldManager string `yaml:"fieldManager"`
+	// FieldValidation is the validation of unknown and duplicate fields on create and update:
+	// Strict (default), Warn or Ignore. It can be overridden with the field_validation provider argument.
+	FieldValidation string `yaml:"fieldValidation"`
+	// Resources configures the resources generated from CRDs by the CRD name, e.g. buckets.prc.com.
+	Resources map[string]ResourceConfig `yaml:"resources"`
 
 	// The directory of the configuration file.
 	// All paths in the configuration file are relative to this directory.
@@ -51,6 +79,11 @@ func NewConfig(filename string) (*Config, error) {
 		return nil, fmt.Errorf("failed to set defaults: %w", err)
 	}
 
+	err = config.validate()
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate config: %w", err)
+	}
+
 	return config, nil
 }
 
@@ -69,5 +102,57 @@ func (c *Config) setDefaults(baseDir string) error {
 	c.SchemasDir = filepath.Join(c.baseDir, c.SchemasDir)
 	c.OutputDir = filepath.Join(c.baseDir, c.OutputDir)
 
+	if c.Mode == "" {
+		c.Mode = ModeCrossplane
+	}
+
+	if c.FieldManager == "" {
+		c.FieldManager = "terraform-provider-" + c.Name
+	}
+
+	if c.FieldValidation == "" {
+		c.FieldValidation = FieldValidationStrict
+	}
+
+	for _, resource := range c.Resources {
+		if resource.Readiness != nil {
+			resource.Readiness.setDefaults()
+		}
+	}
+
+	return nil
+}
+
+func (c *Config) validate() error {
+	if err := validateMode(c.Mode); err != nil {
+		return err
+	}
+
+	if err := validateIgnoreFields(c.IgnoreFields); err != nil {
+		return err
+	}
+
+	if err := validateFieldValidation(c.FieldValidation); err != nil {
+		return err
+	}
+
+	for crdName, resource := range c.Resources {
+		if resource.Mode != "" {
+			if err := validateMode(resource.Mode); err != nil {
+				return fmt.Errorf("invalid resource %s: %w", crdName, err)
+			}
+		}
+
+		if err := validateIgnoreFields(resource.IgnoreFields); err != nil {
+			return fmt.Errorf("invalid resource %s: %w", crdName, err)
+		}
+
+		if resource.Readiness != nil {
+			if err := resource.Readiness.validate(); err != nil {
+				return fmt.Errorf("invalid resource %s: %w", crdName, err)
+			}
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,149 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+
+	file := filepath.Join(t.TempDir(), "tfpgen.yaml")
+	if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	return file
+}
+
+func TestNewConfigDefaults(t *testing.T) {
+	file := writeConfig(t, `
+name: crd
+schemasDir: schemas
+outputDir: out
+resources:
+  buckets.example.com:
+    readiness:
+      conditions:
+      - type: Available
+      - type: Degraded
+        status: "False"
+`)
+
+	cfg, err := NewConfig(file)
+	if err != nil {
+		t.Fatalf("NewConfig() error = %v", err)
+	}
+
+	baseDir := filepath.Dir(file)
+	if cfg.SchemasDir != filepath.Join(baseDir, "schemas") || cfg.OutputDir != filepath.Join(baseDir, "out") {
+		t.Errorf("directories = %s, %s, want them relative to %s", cfg.SchemasDir, cfg.OutputDir, baseDir)
+	}
+	if cfg.Mode != ModeCrossplane {
+		t.Errorf("Mode = %q, want %q", cfg.Mode, ModeCrossplane)
+	}
+	if cfg.FieldManager != "terraform-provider-crd" {
+		t.Errorf("FieldManager = %q, want terraform-provider-crd", cfg.FieldManager)
+	}
+	if cfg.FieldValidation != FieldValidationStrict {
+		t.Errorf("FieldValidation = %q, want %q", cfg.FieldValidation, FieldValidationStrict)
+	}
+
+	want := []ReadinessCondition{{Type: "Available", Status: "True"}, {Type: "Degraded", Status: "False"}}
+	if got := cfg.Resources["buckets.example.com"].Readiness.Conditions; !reflect.DeepEqual(got, want) {
+		t.Errorf("readiness conditions = %+v, want %+v", got, want)
+	}
+}
+
+func TestNewConfigValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "plain mode",
+			content: "mode: plain\n",
+		},
+		{
+			name:    "unsupported mode",
+			content: "mode: operator\n",
+			wantErr: `unsupported mode "operator"`,
+		},
+		{
+			name:    "unsupported resource mode",
+			content: "resources:\n  buckets.example.com:\n    mode: operator\n",
+			wantErr: `invalid resource buckets.example.com: unsupported mode "operator"`,
+		},
+		{
+			name:    "field validation",
+			content: "fieldValidation: Warn\n",
+		},
+		{
+			name:    "unsupported field validation",
+			content: "fieldValidation: strict\n",
+			wantErr: `unsupported field validation "strict"`,
+		},
+		{
+			name:    "ignore field paths",
+			content: "ignoreFields:\n- /spec/resourceRef\n- /status/conditions/reason\n",
+		},
+		{
+			name:    "ignore field without a leading slash",
+			content: "ignoreFields:\n- spec/resourceRef\n",
+			wantErr: `invalid ignore field path "spec/resourceRef"`,
+		},
+		{
+			name:    "ignore field with an empty token",
+			content: "resources:\n  buckets.example.com:\n    ignoreFields:\n    - /spec//resourceRef\n",
+			wantErr: `invalid resource buckets.example.com: invalid ignore field path "/spec//resourceRef"`,
+		},
+		{
+			name:    "readiness without checks",
+			content: "resources:\n  buckets.example.com:\n    readiness: {}\n",
+			wantErr: "readiness doesn't have any checks",
+		},
+		{
+			name:    "readiness with observed generation only",
+			content: "resources:\n  buckets.example.com:\n    readiness:\n      observedGeneration: true\n",
+		},
+		{
+			name:    "readiness condition without a type",
+			content: "resources:\n  buckets.example.com:\n    readiness:\n      conditions:\n      - status: \"True\"\n",
+			wantErr: "readiness condition 0 doesn't have a type",
+		},
+		{
+			name:    "readiness JSONPath without a path",
+			content: "resources:\n  buckets.example.com:\n    readiness:\n      jsonPaths:\n      - value: Ready\n",
+			wantErr: "readiness JSONPath 0 doesn't have a path",
+		},
+		{
+			name:    "readiness JSONPaths",
+			content: "resources:\n  buckets.example.com:\n    readiness:\n      jsonPaths:\n      - path: .status.phase\n      - path: '{.status.conditions[?(@.type==\"Synced\")].status}'\n",
+		},
+		{
+			name:    "invalid readiness JSONPath",
+			content: "resources:\n  buckets.example.com:\n    readiness:\n      jsonPaths:\n      - path: .status.conditions[?(@.type==\n",
+			wantErr: `invalid readiness JSONPath 0 ".status.conditions[?(@.type=="`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewConfig(writeConfig(t, "name: crd\n"+tt.content))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("NewConfig() error = %v", err)
+				}
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("NewConfig() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
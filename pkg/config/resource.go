@@ -0,0 +1,185 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/vvbogdanov87/tfpgen/pkg/jsonpath"
+)
+
+const (
+	// ModeCrossplane generates resources for Crossplane claims and composite resources.
+	// Crossplane specific fields are removed from the schema, the finalizer is tracked
+	// and resources are ready when the Ready condition is True.
+	ModeCrossplane = "crossplane"
+	// ModePlain generates resources for ordinary operator CRDs.
+	// The schema is used as is and the provider doesn't wait for resources unless a readiness rule is configured.
+	ModePlain = "plain"
+)
+
+const (
+	// FieldValidationStrict fails create and update if the object has unknown or duplicate fields.
+	FieldValidationStrict = "Strict"
+	// FieldValidationWarn reports unknown and duplicate fields as warnings.
+	FieldValidationWarn = "Warn"
+	// FieldValidationIgnore drops unknown fields silently.
+	FieldValidationIgnore = "Ignore"
+)
+
+// ResourceConfig configures the resources generated from a CRD.
+type ResourceConfig struct {
+	// Mode overrides the global mode for the CRD.
+	Mode string `yaml:"mode"`
+	// IgnoreFields are JSON-pointer paths of the schema properties that are not generated
+	// in addition to the global IgnoreFields.
+	IgnoreFields []string `yaml:"ignoreFields"`
+	// IgnoreCrossplaneFields overrides the global IgnoreCrossplaneFields for the CRD.
+	IgnoreCrossplaneFields *bool `yaml:"ignoreCrossplaneFields"`
+	// CompositionSelection overrides the global CompositionSelection for the CRD.
+	CompositionSelection *bool `yaml:"compositionSelection"`
+	// ConnectionSecret overrides the global ConnectionSecret for the CRD.
+	ConnectionSecret *bool `yaml:"connectionSecret"`
+	// Readiness is the rule to check whether an object is ready after create and update.
+	// Defaults to the Ready condition with the True status in the crossplane mode.
+	// The condition must match the generation of Crossplane v2 composite resources.
+	// There is no default rule in the plain mode.
+	Readiness *Readiness `yaml:"readiness"`
+}
+
+// Readiness is a rule to check whether an object is ready. All checks must pass.
+type Readiness struct {
+	// Conditions are the status conditions that must have the expected status.
+	Conditions []ReadinessCondition `yaml:"conditions"`
+	// JSONPaths are the JSONPath expressions that must match the expected values.
+	JSONPaths []ReadinessJSONPath `yaml:"jsonPaths"`
+	// ObservedGeneration requires status.observedGeneration and the observedGeneration of the conditions
+	// to match metadata.generation. At least one of them must be reported.
+	ObservedGeneration bool `yaml:"observedGeneration"`
+}
+
+// ReadinessCondition is a status condition check.
+type ReadinessCondition struct {
+	// Type is the condition type, e.g. Ready or Available.
+	Type string `yaml:"type"`
+	// Status is the expected condition status. Defaults to True.
+	Status string `yaml:"status"`
+}
+
+// ReadinessJSONPath is a JSONPath expression check.
+type ReadinessJSONPath struct {
+	// Path is the JSONPath expression, e.g. .status.phase.
+	Path string `yaml:"path"`
+	// Value is the expected value. If it is empty, the path must exist.
+	Value string `yaml:"value"`
+}
+
+// DefaultReadiness returns the readiness rule of resources without the readiness configuration.
+// It is nil in the plain mode.
+// Crossplane v2 reports the observed generation in conditions, so it is checked instead of waiting for any update.
+func DefaultReadiness(mode string, crossplaneV2 bool) *Readiness {
+	if mode == ModePlain {
+		return nil
+	}
+
+	return &Readiness{
+		Conditions:         []ReadinessCondition{{Type: "Ready", Status: "True"}},
+		ObservedGeneration: crossplaneV2,
+	}
+}
+
+// Resource returns the configuration of the resources generated from the CRD.
+func (c *Config) Resource(crdName string) ResourceConfig {
+	resource := c.Resources[crdName]
+
+	if resource.Mode == "" {
+		resource.Mode = c.Mode
+	}
+
+	if resource.IgnoreCrossplaneFields == nil {
+		ignore := resource.Mode == ModeCrossplane
+		if c.IgnoreCrossplaneFields != nil {
+			ignore = *c.IgnoreCrossplaneFields
+		}
+		resource.IgnoreCrossplaneFields = &ignore
+	}
+
+	if resource.CompositionSelection == nil {
+		resource.CompositionSelection = &c.CompositionSelection
+	}
+
+	if resource.ConnectionSecret == nil {
+		resource.ConnectionSecret = &c.ConnectionSecret
+	}
+
+	return resource
+}
+
+// validateJSONPath parses the expression as the generated providers do. The braces are optional.
+func validateJSONPath(expression string) error {
+	if !strings.HasPrefix(expression, "{") {
+		expression = "{" + expression + "}"
+	}
+
+	_, err := jsonpath.Parse("readiness", expression)
+	return err
+}
+
+func validateIgnoreFields(paths []string) error {
+	for _, path := range paths {
+		if !strings.HasPrefix(path, "/") || strings.Contains(path+"/", "//") {
+			return fmt.Errorf("invalid ignore field path %q, expected a JSON pointer, e.g. /spec/resourceRef", path)
+		}
+	}
+
+	return nil
+}
+
+func validateMode(mode string) error {
+	switch mode {
+	case ModeCrossplane, ModePlain:
+		return nil
+	default:
+		return fmt.Errorf("unsupported mode %q, expected %s or %s", mode, ModeCrossplane, ModePlain)
+	}
+}
+
+func validateFieldValidation(fieldValidation string) error {
+	switch fieldValidation {
+	case FieldValidationStrict, FieldValidationWarn, FieldValidationIgnore:
+		return nil
+	default:
+		return fmt.Errorf("unsupported field validation %q, expected %s, %s or %s",
+			fieldValidation, FieldValidationStrict, FieldValidationWarn, FieldValidationIgnore)
+	}
+}
+
+func (r *Readiness) setDefaults() {
+	for i := range r.Conditions {
+		if r.Conditions[i].Status == "" {
+			r.Conditions[i].Status = "True"
+		}
+	}
+}
+
+func (r *Readiness) validate() error {
+	if len(r.Conditions) == 0 && len(r.JSONPaths) == 0 && !r.ObservedGeneration {
+		return fmt.Errorf("readiness doesn't have any checks")
+	}
+
+	for i, condition := range r.Conditions {
+		if condition.Type == "" {
+			return fmt.Errorf("readiness condition %d doesn't have a type", i)
+		}
+	}
+
+	for i, jsonPath := range r.JSONPaths {
+		if jsonPath.Path == "" {
+			return fmt.Errorf("readiness JSONPath %d doesn't have a path", i)
+		}
+		if err := validateJSONPath(jsonPath.Path); err != nil {
+			return fmt.Errorf("invalid readiness JSONPath %d %q: %w", i, jsonPath.Path, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDefaultReadiness(t *testing.T) {
+	tests := []struct {
+		name         string
+		mode         string
+		crossplaneV2 bool
+		want         *Readiness
+	}{
+		{
+			name: "crossplane",
+			mode: ModeCrossplane,
+			want: &Readiness{Conditions: []ReadinessCondition{{Type: "Ready", Status: "True"}}},
+		},
+		{
+			name:         "crossplane v2",
+			mode:         ModeCrossplane,
+			crossplaneV2: true,
+			want:         &Readiness{Conditions: []ReadinessCondition{{Type: "Ready", Status: "True"}}, ObservedGeneration: true},
+		},
+		{
+			name: "plain",
+			mode: ModePlain,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DefaultReadiness(tt.mode, tt.crossplaneV2); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DefaultReadiness() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResource(t *testing.T) {
+	yes, no := true, false
+
+	tests := []struct {
+		name   string
+		config Config
+		want   ResourceConfig
+	}{
+		{
+			name:   "crossplane defaults",
+			config: Config{Mode: ModeCrossplane},
+			want: ResourceConfig{
+				Mode:                   ModeCrossplane,
+				IgnoreCrossplaneFields: &yes,
+				CompositionSelection:   &no,
+				ConnectionSecret:       &no,
+			},
+		},
+		{
+			name:   "plain defaults",
+			config: Config{Mode: ModePlain},
+			want: ResourceConfig{
+				Mode:                   ModePlain,
+				IgnoreCrossplaneFields: &no,
+				CompositionSelection:   &no,
+				ConnectionSecret:       &no,
+			},
+		},
+		{
+			name: "plain resource in crossplane mode",
+			config: Config{
+				Mode:      ModeCrossplane,
+				Resources: map[string]ResourceConfig{"buckets.example.com": {Mode: ModePlain}},
+			},
+			want: ResourceConfig{
+				Mode:                   ModePlain,
+				IgnoreCrossplaneFields: &no,
+				CompositionSelection:   &no,
+				ConnectionSecret:       &no,
+			},
+		},
+		{
+			name: "global settings",
+			config: Config{
+				Mode:                   ModePlain,
+				IgnoreCrossplaneFields: &yes,
+				CompositionSelection:   true,
+				ConnectionSecret:       true,
+			},
+			want: ResourceConfig{
+				Mode:                   ModePlain,
+				IgnoreCrossplaneFields: &yes,
+				CompositionSelection:   &yes,
+				ConnectionSecret:       &yes,
+			},
+		},
+		{
+			name: "resource settings override global settings",
+			config: Config{
+				Mode:                 ModeCrossplane,
+				CompositionSelection: true,
+				ConnectionSecret:     true,
+				Resources: map[string]ResourceConfig{"buckets.example.com": {
+					IgnoreFields:           []string{"/spec/resourceRef"},
+					IgnoreCrossplaneFields: &no,
+					CompositionSelection:   &no,
+					ConnectionSecret:       &no,
+				}},
+			},
+			want: ResourceConfig{
+				Mode:                   ModeCrossplane,
+				IgnoreFields:           []string{"/spec/resourceRef"},
+				IgnoreCrossplaneFields: &no,
+				CompositionSelection:   &no,
+				ConnectionSecret:       &no,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.Resource("buckets.example.com"); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Resource() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,30 +2,54 @@ package generator
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
 	"regexp"
 	"slices"
 	"sort"
 	"strings"
 
+	"github.com/vvbogdanov87/tfpgen/pkg/config"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/yaml"
 )
 
 type Data struct {
-	Group             string
-	Version           string
-	Resource          string
-	Kind              string
-	ResourceName      string
-	PackageName       string
-	ModuleName        string
-	AdditionalImports AdditionalImports
-	SpecProperties    []*Property
-	StatusProperties  []*Property
+	CRDName      string
+	Group        string
+	Version      string
+	Resource     string
+	Kind         string
+	Namespaced   bool
+	Crossplane   bool
+	CrossplaneV2 bool
+	// CompositionSelection is set if the Crossplane composition selection fields are generated.
+	// CompositionFields are the Go names of the fields that are cleared if they are not managed by Terraform.
+	CompositionSelection bool
+	CompositionFields    []string
+	// ConnectionSecret is set if the resource sets writeConnectionSecretToRef and reads the connection details.
+	// ConnectionSecretNamespace is set if the reference has a namespace, e.g. for cluster-scoped composite resources.
+	ConnectionSecret          bool
+	ConnectionSecretNamespace bool
+	// Claim is set for Crossplane v1 claims. Crossplane deletes the composite resource of a deleted claim,
+	// so claims can't be orphaned.
+	Claim              bool
+	ResourceName       string
+	PluralName         string
+	PackageName        string
+	Deprecated         bool
+	DeprecationMessage string
+	Readiness          *config.Readiness
+	ModuleName         string
+	AdditionalImports  AdditionalImports
+	SpecProperties     []*Property
+	StatusProperties   []*Property
 }
 
 type Property struct {
@@ -38,6 +62,7 @@ type Property struct {
 	ElementType       string
 	Required          bool
 	Optional          bool
+	Nullable          bool // Nullable primitives are pointers omitted from the object if they are null
 	Computed          bool
 	Default           string
 	ValidatorsType    string
@@ -67,16 +92,35 @@ type AdditionalImports struct {
 
 var capitalizer = cases.Title(language.English, cases.NoLower)
 
-func parseSchema(file string) (*Data, error) {
-	crd, err := loadSchema(file)
+func parseSchema(file string, cfg *config.Config) ([]*Data, error) {
+	documents, err := loadSchemas(file)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load schema: %w", err)
+		return nil, fmt.Errorf("failed to load schemas: %w", err)
 	}
 
-	return crdToData(crd)
+	var dataList []*Data
+
+	for _, document := range documents {
+		data, err := crdToData(document.crd, cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert CRD %s in yaml document %d from file %s: %w", document.crd.Name, document.index, file, err)
+		}
+
+		dataList = append(dataList, data...)
+	}
+
+	return dataList, nil
+}
+
+// schemaDocument is a CRD and the index of the YAML document it is read from.
+type schemaDocument struct {
+	index int
+	crd   *apiextensionsv1.CustomResourceDefinition
 }
 
-func loadSchema(filename string) (*apiextensionsv1.CustomResourceDefinition, error) {
+// loadSchemas reads all YAML documents from the file and returns the CRDs.
+// Documents of other kinds are skipped.
+func loadSchemas(filename string) ([]schemaDocument, error) {
 	file, err := os.Open(filename)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open file %s: %w", filename, err)
@@ -86,57 +130,128 @@ func loadSchema(filename string) (*apiextensionsv1.CustomResourceDefinition, err
 	bufr := bufio.NewReader(file)
 	yamlReader := yaml.NewYAMLReader(bufr)
 
-	data, err := yamlReader.Read()
-	if err != nil {
-		return nil, fmt.Errorf("failed to read yaml from file %s: %w", filename, err)
+	var documents []schemaDocument
+
+	for index := 0; ; index++ {
+		data, err := yamlReader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read yaml document %d from file %s: %w", index, filename, err)
+		}
+
+		var typeMeta metav1.TypeMeta
+		if err := yaml.Unmarshal(data, &typeMeta); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal yaml document %d from file %s: %w", index, filename, err)
+		}
+
+		// Empty documents and manifests of other kinds (e.g. Namespaces, RBAC) are skipped
+		if typeMeta.Kind != "CustomResourceDefinition" {
+			continue
+		}
+
+		if typeMeta.APIVersion != apiextensionsv1.SchemeGroupVersion.String() {
+			return nil, fmt.Errorf("unsupported CRD apiVersion %s in yaml document %d from file %s", typeMeta.APIVersion, index, filename)
+		}
+
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		if err := yaml.Unmarshal(data, crd); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal CRD from yaml document %d from file %s: %w", index, filename, err)
+		}
+
+		documents = append(documents, schemaDocument{index: index, crd: crd})
+	}
+
+	return documents, nil
+}
+
+// crdToData converts every served version of the CRD to the template data.
+func crdToData(crd *apiextensionsv1.CustomResourceDefinition, cfg *config.Config) ([]*Data, error) {
+	var served []*apiextensionsv1.CustomResourceDefinitionVersion
+	for i := range crd.Spec.Versions {
+		if crd.Spec.Versions[i].Served {
+			served = append(served, &crd.Spec.Versions[i])
+		}
 	}
 
-	crd := &apiextensionsv1.CustomResourceDefinition{}
-	if err := yaml.Unmarshal(data, crd); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal yaml from file %s: %w", filename, err)
+	resourceConfig := cfg.Resource(crd.Name)
+
+	dataList := make([]*Data, 0, len(served))
+	for _, version := range served {
+		resourceName := strings.ToLower(crd.Spec.Names.Kind)
+		pluralName := strings.ToLower(crd.Spec.Names.Plural)
+		// Resource names must be unique, so every version gets a suffix unless it is the only one.
+		// The storage version can optionally keep the name without the suffix.
+		if len(served) > 1 && !(cfg.DefaultToStorageVersion && version.Storage) {
+			resourceName += "_" + strings.ToLower(version.Name)
+			pluralName += "_" + strings.ToLower(version.Name)
+		}
+
+		// Detect the Crossplane v2 layout before the machinery fields are removed
+		crossplaneV2 := resourceConfig.Mode == config.ModeCrossplane && isCrossplaneV2(version)
+
+		// The reference is removed with the Crossplane fields, so it is detected before
+		connectionSecret, connectionSecretNamespace := false, false
+		if *resourceConfig.ConnectionSecret && resourceConfig.Mode == config.ModeCrossplane {
+			connectionSecret, connectionSecretNamespace = connectionSecretRef(version)
+			// The reference is managed by the provider, so it must not be generated as a spec attribute
+			connectionSecret = connectionSecret && *resourceConfig.IgnoreCrossplaneFields
+			if !connectionSecret && cfg.Resources[crd.Name].ConnectionSecret != nil {
+				slog.Warn("connection secret is not supported", "crd", crd.Name, "version", version.Name)
+			}
+		}
+
+		// Only claims have the compositeDeletePolicy field
+		_, claim := specProperty(version, "compositeDeletePolicy")
+		claim = claim && resourceConfig.Mode == config.ModeCrossplane
+
+		versionConfig := resourceConfig
+		if versionConfig.Readiness == nil {
+			versionConfig.Readiness = config.DefaultReadiness(resourceConfig.Mode, crossplaneV2)
+		}
+
+		if version.Schema != nil && version.Schema.OpenAPIV3Schema != nil {
+			schema := version.Schema.OpenAPIV3Schema
+
+			// The preset and the global fields may be missing in some CRDs, so only the per-CRD fields are reported
+			if *resourceConfig.IgnoreCrossplaneFields {
+				ignoreFields(schema, crossplanePreset(schema, crossplaneV2, *resourceConfig.CompositionSelection))
+			}
+			ignoreFields(schema, cfg.IgnoreFields)
+			for _, path := range ignoreFields(schema, resourceConfig.IgnoreFields) {
+				slog.Warn("ignored field not found", "crd", crd.Name, "version", version.Name, "path", path)
+			}
+		}
+
+		data, err := versionToData(crd, version, versionConfig, crossplaneV2, resourceName, pluralName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert version %s: %w", version.Name, err)
+		}
+
+		data.ConnectionSecret = connectionSecret
+		data.ConnectionSecretNamespace = connectionSecret && connectionSecretNamespace && !data.Namespaced
+		data.Claim = claim
+
+		dataList = append(dataList, data)
 	}
 
-	return crd, nil
+	return dataList, nil
 }
 
-func crdToData(crd *apiextensionsv1.CustomResourceDefinition) (*Data, error) {
+func versionToData(crd *apiextensionsv1.CustomResourceDefinition, version *apiextensionsv1.CustomResourceDefinitionVersion, resourceConfig config.ResourceConfig, crossplaneV2 bool, resourceName, pluralName string) (*Data, error) {
 	group := crd.Spec.Group
 	kind := crd.Spec.Names.Kind
-	resourceName := strings.ToLower(kind)
 
-	// We assume that there is only one version
-	version := crd.Spec.Versions[0]
+	if version.Schema == nil || version.Schema.OpenAPIV3Schema == nil {
+		return nil, fmt.Errorf("version %s doesn't have an OpenAPI schema", version.Name)
+	}
 
 	schema := version.Schema.OpenAPIV3Schema
+	crossplane := resourceConfig.Mode == config.ModeCrossplane
 
-	// Delete crossplane specific spec fields
 	spec := schema.Properties["spec"]
-	crossplaneSpecFields := [...]string{
-		"compositeDeletePolicy",
-		"compositionRef",
-		"compositionRevisionRef",
-		"compositionRevisionSelector",
-		"compositionSelector",
-		"compositionUpdatePolicy",
-		"publishConnectionDetailsTo",
-		"resourceRef",
-		"writeConnectionSecretToRef",
-	}
-
-	for _, field := range crossplaneSpecFields {
-		delete(spec.Properties, field)
-	}
-
-	// Delete crossplane specific status fields
 	status := schema.Properties["status"]
-	crossplaneStatusFields := [...]string{
-		"connectionDetails",
-		"conditions",
-	}
-
-	for _, field := range crossplaneStatusFields {
-		delete(status.Properties, field)
-	}
 
 	var additionalImports AdditionalImports
 
@@ -149,19 +264,242 @@ func crdToData(crd *apiextensionsv1.CustomResourceDefinition) (*Data, error) {
 		return nil, fmt.Errorf("failed to get status properties: %w", err)
 	}
 
+	compositionSelection := crossplane && *resourceConfig.CompositionSelection
+
+	var composition []string
+	if compositionSelection {
+		composition = compositionFields(specProperties, crossplaneV2)
+	}
+
 	return &Data{
-		Kind:              kind,
-		Group:             group,
-		Resource:          crd.Spec.Names.Plural,
-		Version:           version.Name,
-		ResourceName:      resourceName,
-		PackageName:       strings.ReplaceAll(group, ".", "_") + "_" + resourceName + "_" + strings.ToLower(version.Name),
-		AdditionalImports: additionalImports,
-		SpecProperties:    specProperties,
-		StatusProperties:  statusProperties,
+		CRDName:              crd.Name,
+		Kind:                 kind,
+		Namespaced:           crd.Spec.Scope == apiextensionsv1.NamespaceScoped,
+		Crossplane:           crossplane,
+		CrossplaneV2:         crossplaneV2,
+		CompositionSelection: compositionSelection,
+		CompositionFields:    composition,
+		Group:                group,
+		Resource:             crd.Spec.Names.Plural,
+		Version:              version.Name,
+		ResourceName:         resourceName,
+		PluralName:           pluralName,
+		PackageName:          strings.ReplaceAll(group, ".", "_") + "_" + strings.ToLower(kind) + "_" + strings.ToLower(version.Name),
+		Deprecated:           version.Deprecated,
+		DeprecationMessage:   deprecationMessage(group, kind, version),
+		Readiness:            resourceConfig.Readiness,
+		AdditionalImports:    additionalImports,
+		SpecProperties:       specProperties,
+		StatusProperties:     statusProperties,
 	}, nil
 }
 
+// crossplaneIgnoreFields are the fields managed by Crossplane.
+// They are ignored by default in the crossplane mode.
+var crossplaneIgnoreFields = []string{
+	// Crossplane v1 claims and composite resources
+	"/spec/claimRef",
+	"/spec/compositeDeletePolicy",
+	"/spec/compositionRef",
+	"/spec/compositionRevisionRef",
+	"/spec/compositionRevisionSelector",
+	"/spec/compositionSelector",
+	"/spec/compositionUpdatePolicy",
+	"/spec/environmentConfigRefs",
+	"/spec/publishConnectionDetailsTo",
+	"/spec/resourceRef",
+	"/spec/resourceRefs",
+	"/spec/writeConnectionSecretToRef",
+	"/status/claimConditionTypes",
+	"/status/connectionDetails",
+	"/status/conditions",
+	// Crossplane v2 composite resources keep the machinery fields under spec.crossplane
+	"/spec/crossplane",
+}
+
+// compositionSelectionFields are the Crossplane fields to choose a composition and pin its revision.
+var compositionSelectionFields = []string{
+	"compositionRef",
+	"compositionRevisionRef",
+	"compositionRevisionSelector",
+	"compositionSelector",
+	"compositionUpdatePolicy",
+}
+
+// crossplanePreset returns the Crossplane fields to ignore.
+// The composition selection fields are kept if compositionSelection is enabled.
+func crossplanePreset(schema *apiextensionsv1.JSONSchemaProps, crossplaneV2, compositionSelection bool) []string {
+	if !compositionSelection {
+		return crossplaneIgnoreFields
+	}
+
+	var preset []string
+	for _, path := range crossplaneIgnoreFields {
+		name := strings.TrimPrefix(path, "/spec/")
+		if slices.Contains(compositionSelectionFields, name) {
+			continue
+		}
+
+		// Only the composition selection fields of the Crossplane v2 machinery are kept
+		if crossplaneV2 && path == "/spec/crossplane" {
+			for name := range schema.Properties["spec"].Properties["crossplane"].Properties {
+				if !slices.Contains(compositionSelectionFields, name) {
+					preset = append(preset, "/spec/crossplane/"+name)
+				}
+			}
+			continue
+		}
+
+		preset = append(preset, path)
+	}
+
+	return preset
+}
+
+// compositionFields returns the Go names of the composition selection fields that can be cleared.
+// Crossplane sets them when it selects a composition, so they are kept in the state only if they are managed by Terraform.
+// Primitive fields (compositionUpdatePolicy) are made nullable, so they are not sent if they are not configured.
+func compositionFields(specProperties []*Property, crossplaneV2 bool) []string {
+	properties := specProperties
+	if crossplaneV2 {
+		properties = nil
+		for _, prop := range specProperties {
+			if prop.Name == "crossplane" {
+				properties = prop.Properties
+			}
+		}
+	}
+
+	var fields []string
+	for _, prop := range properties {
+		if !slices.Contains(compositionSelectionFields, prop.Name) {
+			continue
+		}
+
+		if prop.GoType != "struct" && prop.GoType != "map" && prop.GoType != "array" && !prop.Nullable {
+			prop.GoType = "*" + prop.GoType
+			prop.Nullable = true
+		}
+		fields = append(fields, prop.FieldName)
+	}
+
+	return fields
+}
+
+// isCrossplaneV2 checks whether the CRD version is generated from a Crossplane v2 XRD.
+// Crossplane v2 composite resources have the spec.crossplane object with the composition fields.
+func isCrossplaneV2(version *apiextensionsv1.CustomResourceDefinitionVersion) bool {
+	if version.Schema == nil || version.Schema.OpenAPIV3Schema == nil {
+		return false
+	}
+
+	machinery, ok := version.Schema.OpenAPIV3Schema.Properties["spec"].Properties["crossplane"]
+	if !ok {
+		return false
+	}
+
+	_, compositionRef := machinery.Properties["compositionRef"]
+	_, resourceRefs := machinery.Properties["resourceRefs"]
+
+	return compositionRef || resourceRefs
+}
+
+// connectionSecretRef checks whether the CRD version has the Crossplane v1 writeConnectionSecretToRef field.
+// It also returns whether the reference has a namespace. Claims write the secret to their own namespace.
+func connectionSecretRef(version *apiextensionsv1.CustomResourceDefinitionVersion) (bool, bool) {
+	ref, ok := specProperty(version, "writeConnectionSecretToRef")
+	if !ok {
+		return false, false
+	}
+
+	_, namespace := ref.Properties["namespace"]
+
+	return true, namespace
+}
+
+// specProperty returns the top-level spec property of the CRD version.
+func specProperty(version *apiextensionsv1.CustomResourceDefinitionVersion, name string) (apiextensionsv1.JSONSchemaProps, bool) {
+	if version.Schema == nil || version.Schema.OpenAPIV3Schema == nil {
+		return apiextensionsv1.JSONSchemaProps{}, false
+	}
+
+	prop, ok := version.Schema.OpenAPIV3Schema.Properties["spec"].Properties[name]
+
+	return prop, ok
+}
+
+// ignoreFields deletes the properties at the JSON-pointer paths from the schema.
+// Array items and map values are traversed implicitly, e.g. /status/conditions/reason.
+// It returns the paths that were not found.
+func ignoreFields(schema *apiextensionsv1.JSONSchemaProps, paths []string) []string {
+	var notFound []string
+
+	for _, path := range paths {
+		tokens := strings.Split(strings.TrimPrefix(path, "/"), "/")
+		for i, token := range tokens {
+			// JSON pointer escaping
+			tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		}
+
+		if !deleteProperty(schema, tokens) {
+			notFound = append(notFound, path)
+		}
+	}
+
+	return notFound
+}
+
+func deleteProperty(schema *apiextensionsv1.JSONSchemaProps, tokens []string) bool {
+	schema = elementSchema(schema)
+
+	prop, ok := schema.Properties[tokens[0]]
+	if !ok {
+		return false
+	}
+
+	if len(tokens) == 1 {
+		delete(schema.Properties, tokens[0])
+		schema.Required = slices.DeleteFunc(slices.Clone(schema.Required), func(name string) bool {
+			return name == tokens[0]
+		})
+
+		return true
+	}
+
+	// Properties are stored by value, so the modified property is written back
+	found := deleteProperty(&prop, tokens[1:])
+	schema.Properties[tokens[0]] = prop
+
+	return found
+}
+
+// elementSchema returns the schema of array items and map values, or the schema itself.
+func elementSchema(schema *apiextensionsv1.JSONSchemaProps) *apiextensionsv1.JSONSchemaProps {
+	for {
+		switch {
+		case schema.Items != nil && schema.Items.Schema != nil:
+			schema = schema.Items.Schema
+		case schema.AdditionalProperties != nil && schema.AdditionalProperties.Schema != nil:
+			schema = schema.AdditionalProperties.Schema
+		default:
+			return schema
+		}
+	}
+}
+
+// deprecationMessage returns the message shown when a deprecated version is used.
+func deprecationMessage(group, kind string, version *apiextensionsv1.CustomResourceDefinitionVersion) string {
+	if !version.Deprecated {
+		return ""
+	}
+
+	if version.DeprecationWarning != nil {
+		return cleanDescription(*version.DeprecationWarning)
+	}
+
+	return fmt.Sprintf("%s/%s %s is deprecated", group, version.Name, kind)
+}
+
 func crdProperties(schema *apiextensionsv1.JSONSchemaProps, additionalImports *AdditionalImports, computed bool) ([]*Property, error) {
 	properties := make([]*Property, 0, len(schema.Properties))
 	// Iterate over the properties of the schema. Recursively call crdProperties.
@@ -171,6 +509,12 @@ func crdProperties(schema *apiextensionsv1.JSONSchemaProps, additionalImports *A
 			return nil, fmt.Errorf("failed to convert CRD type: %w", err)
 		}
 
+		// Objects without properties (e.g. x-kubernetes-preserve-unknown-fields) can't be mapped to Terraform attributes
+		if goType == "" || goType == "*" {
+			slog.Warn("skipping property with unsupported type", "property", name, "type", sProp.Type)
+			continue
+		}
+
 		var nestedProperties []*Property
 
 		switch goType {
@@ -207,9 +551,12 @@ func crdProperties(schema *apiextensionsv1.JSONSchemaProps, additionalImports *A
 		properties = append(properties, prop)
 	}
 
-	// Mark required properties
+	// Mark required properties. Computed (status) properties are set by Kubernetes, so they are never required.
 	for _, prop := range properties {
-		if slices.Contains(schema.Required, prop.Name) {
+		if computed {
+			prop.Required = false
+			prop.Optional = false
+		} else if slices.Contains(schema.Required, prop.Name) {
 			prop.Required = true
 			prop.Optional = false
 		} else {
@@ -245,7 +592,10 @@ func convertCrdType(sProp *apiextensionsv1.JSONSchemaProps, additionalImports *A
 		argumentType = "schema.StringAttribute"
 
 		validatorsType = "validator.String"
-		validators = getStringValidators(sProp, additionalImports)
+		// Computed attributes are not configured, so they are not validated
+		if !computed {
+			validators = getStringValidators(sProp, additionalImports)
+		}
 
 		planModifiersType = "planmodifier.String"
 		if immutable {
@@ -263,7 +613,10 @@ func convertCrdType(sProp *apiextensionsv1.JSONSchemaProps, additionalImports *A
 		argumentType = "schema.Int64Attribute"
 
 		validatorsType = "validator.Int64"
-		validators = getIntegerValidators(sProp, additionalImports)
+		// Computed attributes are not configured, so they are not validated
+		if !computed {
+			validators = getIntegerValidators(sProp, additionalImports)
+		}
 
 		planModifiersType = "planmodifier.Int64"
 		if immutable {
@@ -281,7 +634,10 @@ func convertCrdType(sProp *apiextensionsv1.JSONSchemaProps, additionalImports *A
 		argumentType = "schema.Float64Attribute"
 
 		validatorsType = "validator.Float64"
-		validators = getNumberValidators(sProp, additionalImports)
+		// Computed attributes are not configured, so they are not validated
+		if !computed {
+			validators = getNumberValidators(sProp, additionalImports)
+		}
 
 		planModifiersType = "planmodifier.Float64"
 		if immutable {
@@ -335,7 +691,8 @@ func convertCrdType(sProp *apiextensionsv1.JSONSchemaProps, additionalImports *A
 		}
 	}
 
-	if computed {
+	// Nested types are nullable already
+	if computed && goType != "struct" && goType != "map" && goType != "array" {
 		goType = "*" + goType
 	}
 
@@ -0,0 +1,630 @@
+package generator
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/vvbogdanov87/tfpgen/pkg/config"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+)
+
+// crdDocument is a minimal CRD with the kind and the served versions. The first version is the storage version.
+func crdDocument(kind string, versions ...string) string {
+	var doc strings.Builder
+	doc.WriteString(`apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: ` + strings.ToLower(kind) + `s.example.com
+spec:
+  group: example.com
+  names:
+    kind: ` + kind + `
+    plural: ` + strings.ToLower(kind) + `s
+  scope: Namespaced
+  versions:
+`)
+	for i, version := range versions {
+		doc.WriteString(`  - name: ` + version + `
+    served: true
+    storage: ` + strconv.FormatBool(i == 0) + `
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              size:
+                type: string
+`)
+	}
+
+	return doc.String()
+}
+
+func writeSchema(t *testing.T, content string) string {
+	t.Helper()
+
+	file := filepath.Join(t.TempDir(), "schema.yaml")
+	if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+		t.Fatalf("write schema: %v", err)
+	}
+
+	return file
+}
+
+func TestLoadSchemas(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+		wantIdx []int
+		wantErr string
+	}{
+		{
+			name:    "single document",
+			content: crdDocument("Bucket", "v1"),
+			want:    []string{"buckets.example.com"},
+			wantIdx: []int{0},
+		},
+		{
+			name:    "multiple documents",
+			content: crdDocument("Bucket", "v1") + "---\n" + crdDocument("Queue", "v1"),
+			want:    []string{"buckets.example.com", "queues.example.com"},
+			wantIdx: []int{0, 1},
+		},
+		{
+			name: "other kinds and empty documents are skipped",
+			content: "apiVersion: v1\nkind: Namespace\nmetadata:\n  name: test\n---\n---\n" +
+				crdDocument("Bucket", "v1"),
+			want:    []string{"buckets.example.com"},
+			wantIdx: []int{1},
+		},
+		{
+			name:    "no CRDs",
+			content: "apiVersion: v1\nkind: Namespace\nmetadata:\n  name: test\n",
+		},
+		{
+			name: "v1beta1 CRDs are rejected",
+			content: crdDocument("Bucket", "v1") + "---\n" +
+				strings.Replace(crdDocument("Queue", "v1"), "apiextensions.k8s.io/v1", "apiextensions.k8s.io/v1beta1", 1),
+			wantErr: "unsupported CRD apiVersion apiextensions.k8s.io/v1beta1 in yaml document 1",
+		},
+		{
+			name:    "invalid yaml",
+			content: crdDocument("Bucket", "v1") + "---\nkind: [\n",
+			wantErr: "yaml document 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			documents, err := loadSchemas(writeSchema(t, tt.content))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("loadSchemas() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("loadSchemas() error = %v", err)
+			}
+
+			if len(documents) != len(tt.want) {
+				t.Fatalf("loadSchemas() returned %d CRDs, want %d", len(documents), len(tt.want))
+			}
+			for i, document := range documents {
+				if document.crd.Name != tt.want[i] || document.index != tt.wantIdx[i] {
+					t.Errorf("document %d = %s at index %d, want %s at index %d", i, document.crd.Name, document.index, tt.want[i], tt.wantIdx[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseSchemaErrorHasDocumentIndex(t *testing.T) {
+	// The second CRD has a version without a schema
+	content := crdDocument("Bucket", "v1") + "---\n" +
+		strings.Split(crdDocument("Queue", "v1"), "    schema:")[0]
+
+	_, err := parseSchema(writeSchema(t, content), &config.Config{Mode: config.ModeCrossplane})
+	if err == nil {
+		t.Fatal("parseSchema() error = nil")
+	}
+	if want := "CRD queues.example.com in yaml document 1"; !strings.Contains(err.Error(), want) {
+		t.Errorf("parseSchema() error = %v, want %q", err, want)
+	}
+}
+
+func TestCRDToDataVersionSuffix(t *testing.T) {
+	tests := []struct {
+		name                    string
+		versions                []string
+		defaultToStorageVersion bool
+		wantResources           []string
+		wantPlurals             []string
+	}{
+		{
+			name:          "single version",
+			versions:      []string{"v1"},
+			wantResources: []string{"bucket"},
+			wantPlurals:   []string{"buckets"},
+		},
+		{
+			name:          "multiple versions",
+			versions:      []string{"v1", "v2"},
+			wantResources: []string{"bucket_v1", "bucket_v2"},
+			wantPlurals:   []string{"buckets_v1", "buckets_v2"},
+		},
+		{
+			name:                    "storage version without suffix",
+			versions:                []string{"v1", "v2"},
+			defaultToStorageVersion: true,
+			wantResources:           []string{"bucket", "bucket_v2"},
+			wantPlurals:             []string{"buckets", "buckets_v2"},
+		},
+		{
+			name:                    "single version with default to storage version",
+			versions:                []string{"v1"},
+			defaultToStorageVersion: true,
+			wantResources:           []string{"bucket"},
+			wantPlurals:             []string{"buckets"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			documents, err := loadSchemas(writeSchema(t, crdDocument("Bucket", tt.versions...)))
+			if err != nil {
+				t.Fatalf("loadSchemas() error = %v", err)
+			}
+
+			cfg := &config.Config{Mode: config.ModeCrossplane, DefaultToStorageVersion: tt.defaultToStorageVersion}
+			dataList, err := crdToData(documents[0].crd, cfg)
+			if err != nil {
+				t.Fatalf("crdToData() error = %v", err)
+			}
+
+			if len(dataList) != len(tt.wantResources) {
+				t.Fatalf("crdToData() returned %d versions, want %d", len(dataList), len(tt.wantResources))
+			}
+			for i, data := range dataList {
+				if data.ResourceName != tt.wantResources[i] || data.PluralName != tt.wantPlurals[i] {
+					t.Errorf("version %s names = %s, %s, want %s, %s", data.Version, data.ResourceName, data.PluralName, tt.wantResources[i], tt.wantPlurals[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCRDToDataSkipsVersionsNotServed(t *testing.T) {
+	documents, err := loadSchemas(writeSchema(t, crdDocument("Bucket", "v1", "v2")))
+	if err != nil {
+		t.Fatalf("loadSchemas() error = %v", err)
+	}
+	crd := documents[0].crd
+	crd.Spec.Versions[1].Served = false
+
+	dataList, err := crdToData(crd, &config.Config{Mode: config.ModeCrossplane})
+	if err != nil {
+		t.Fatalf("crdToData() error = %v", err)
+	}
+
+	// The only served version doesn't get a suffix
+	if len(dataList) != 1 || dataList[0].ResourceName != "bucket" || dataList[0].Version != "v1" {
+		t.Errorf("crdToData() = %+v, want the v1 bucket resource", dataList)
+	}
+}
+
+// ignoreSchema is a schema with nested objects, arrays of objects and maps of objects.
+func ignoreSchema() *apiextensionsv1.JSONSchemaProps {
+	condition := apiextensionsv1.JSONSchemaProps{
+		Type:     "object",
+		Required: []string{"type", "reason"},
+		Properties: map[string]apiextensionsv1.JSONSchemaProps{
+			"type":   {Type: "string"},
+			"reason": {Type: "string"},
+		},
+	}
+
+	return &apiextensionsv1.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]apiextensionsv1.JSONSchemaProps{
+			"spec": {
+				Type:     "object",
+				Required: []string{"size", "resourceRef"},
+				Properties: map[string]apiextensionsv1.JSONSchemaProps{
+					"size":        {Type: "string"},
+					"resourceRef": {Type: "object", Properties: map[string]apiextensionsv1.JSONSchemaProps{"name": {Type: "string"}}},
+					"a/b":         {Type: "string"},
+					"tags": {
+						Type:                 "object",
+						AdditionalProperties: &apiextensionsv1.JSONSchemaPropsOrBool{Schema: &condition},
+					},
+				},
+			},
+			"status": {
+				Type: "object",
+				Properties: map[string]apiextensionsv1.JSONSchemaProps{
+					"conditions": {Type: "array", Items: &apiextensionsv1.JSONSchemaPropsOrArray{Schema: &condition}},
+				},
+			},
+		},
+	}
+}
+
+func TestIgnoreFields(t *testing.T) {
+	tests := []struct {
+		name         string
+		paths        []string
+		wantNotFound []string
+		check        func(t *testing.T, schema *apiextensionsv1.JSONSchemaProps)
+	}{
+		{
+			name:  "nested property and required list",
+			paths: []string{"/spec/resourceRef"},
+			check: func(t *testing.T, schema *apiextensionsv1.JSONSchemaProps) {
+				spec := schema.Properties["spec"]
+				if _, ok := spec.Properties["resourceRef"]; ok {
+					t.Error("resourceRef is not deleted")
+				}
+				if len(spec.Required) != 1 || spec.Required[0] != "size" {
+					t.Errorf("required = %v, want [size]", spec.Required)
+				}
+			},
+		},
+		{
+			name:  "property of array items",
+			paths: []string{"/status/conditions/reason"},
+			check: func(t *testing.T, schema *apiextensionsv1.JSONSchemaProps) {
+				items := schema.Properties["status"].Properties["conditions"].Items.Schema
+				if _, ok := items.Properties["reason"]; ok {
+					t.Error("reason is not deleted from the array items")
+				}
+				if len(items.Required) != 1 || items.Required[0] != "type" {
+					t.Errorf("required = %v, want [type]", items.Required)
+				}
+			},
+		},
+		{
+			name:  "property of map values",
+			paths: []string{"/spec/tags/type"},
+			check: func(t *testing.T, schema *apiextensionsv1.JSONSchemaProps) {
+				values := schema.Properties["spec"].Properties["tags"].AdditionalProperties.Schema
+				if _, ok := values.Properties["type"]; ok {
+					t.Error("type is not deleted from the map values")
+				}
+			},
+		},
+		{
+			name:  "escaped slash",
+			paths: []string{"/spec/a~1b"},
+			check: func(t *testing.T, schema *apiextensionsv1.JSONSchemaProps) {
+				if _, ok := schema.Properties["spec"].Properties["a/b"]; ok {
+					t.Error("a/b is not deleted")
+				}
+			},
+		},
+		{
+			name:         "missing paths are reported",
+			paths:        []string{"/spec/missing", "/spec/size", "/status/conditions/missing", "/missing/size"},
+			wantNotFound: []string{"/spec/missing", "/status/conditions/missing", "/missing/size"},
+			check: func(t *testing.T, schema *apiextensionsv1.JSONSchemaProps) {
+				if _, ok := schema.Properties["spec"].Properties["size"]; ok {
+					t.Error("size is not deleted")
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema := ignoreSchema()
+
+			notFound := ignoreFields(schema, tt.paths)
+			if strings.Join(notFound, ",") != strings.Join(tt.wantNotFound, ",") {
+				t.Errorf("ignoreFields() = %v, want %v", notFound, tt.wantNotFound)
+			}
+
+			tt.check(t, schema)
+		})
+	}
+}
+
+func TestElementSchema(t *testing.T) {
+	leaf := &apiextensionsv1.JSONSchemaProps{Type: "string"}
+
+	tests := []struct {
+		name   string
+		schema *apiextensionsv1.JSONSchemaProps
+		want   *apiextensionsv1.JSONSchemaProps
+	}{
+		{
+			name:   "scalar",
+			schema: leaf,
+			want:   leaf,
+		},
+		{
+			name:   "array items",
+			schema: &apiextensionsv1.JSONSchemaProps{Type: "array", Items: &apiextensionsv1.JSONSchemaPropsOrArray{Schema: leaf}},
+			want:   leaf,
+		},
+		{
+			name:   "map values",
+			schema: &apiextensionsv1.JSONSchemaProps{Type: "object", AdditionalProperties: &apiextensionsv1.JSONSchemaPropsOrBool{Schema: leaf}},
+			want:   leaf,
+		},
+		{
+			name: "array of maps",
+			schema: &apiextensionsv1.JSONSchemaProps{
+				Type: "array",
+				Items: &apiextensionsv1.JSONSchemaPropsOrArray{Schema: &apiextensionsv1.JSONSchemaProps{
+					Type:                 "object",
+					AdditionalProperties: &apiextensionsv1.JSONSchemaPropsOrBool{Schema: leaf},
+				}},
+			},
+			want: leaf,
+		},
+		{
+			name:   "map without a value schema",
+			schema: &apiextensionsv1.JSONSchemaProps{Type: "object", AdditionalProperties: &apiextensionsv1.JSONSchemaPropsOrBool{Allows: true}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := tt.want
+			if want == nil {
+				want = tt.schema
+			}
+
+			if got := elementSchema(tt.schema); got != want {
+				t.Errorf("elementSchema() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+// specVersion is a CRD version with the spec properties.
+func specVersion(properties map[string]apiextensionsv1.JSONSchemaProps) *apiextensionsv1.CustomResourceDefinitionVersion {
+	return &apiextensionsv1.CustomResourceDefinitionVersion{
+		Name: "v1",
+		Schema: &apiextensionsv1.CustomResourceValidation{
+			OpenAPIV3Schema: &apiextensionsv1.JSONSchemaProps{
+				Type: "object",
+				Properties: map[string]apiextensionsv1.JSONSchemaProps{
+					"spec": {Type: "object", Properties: properties},
+				},
+			},
+		},
+	}
+}
+
+func TestIsCrossplaneV2(t *testing.T) {
+	object := apiextensionsv1.JSONSchemaProps{Type: "object"}
+
+	tests := []struct {
+		name    string
+		version *apiextensionsv1.CustomResourceDefinitionVersion
+		want    bool
+	}{
+		{
+			name:    "no schema",
+			version: &apiextensionsv1.CustomResourceDefinitionVersion{Name: "v1"},
+		},
+		{
+			name:    "crossplane v1 composite resource",
+			version: specVersion(map[string]apiextensionsv1.JSONSchemaProps{"compositionRef": object, "resourceRefs": object}),
+		},
+		{
+			name: "crossplane v2 composite resource",
+			version: specVersion(map[string]apiextensionsv1.JSONSchemaProps{"crossplane": {
+				Type:       "object",
+				Properties: map[string]apiextensionsv1.JSONSchemaProps{"compositionRef": object, "resourceRefs": object},
+			}}),
+			want: true,
+		},
+		{
+			name: "crossplane v2 composite resource with resource references only",
+			version: specVersion(map[string]apiextensionsv1.JSONSchemaProps{"crossplane": {
+				Type:       "object",
+				Properties: map[string]apiextensionsv1.JSONSchemaProps{"resourceRefs": object},
+			}}),
+			want: true,
+		},
+		{
+			name: "user-defined crossplane property",
+			version: specVersion(map[string]apiextensionsv1.JSONSchemaProps{"crossplane": {
+				Type:       "object",
+				Properties: map[string]apiextensionsv1.JSONSchemaProps{"version": {Type: "string"}},
+			}}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isCrossplaneV2(tt.version); got != tt.want {
+				t.Errorf("isCrossplaneV2() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompositionFields(t *testing.T) {
+	selection := func() []*Property {
+		return []*Property{
+			{Name: "compositionRef", FieldName: "CompositionRef", GoType: "struct"},
+			{Name: "compositionSelector", FieldName: "CompositionSelector", GoType: "struct"},
+			{Name: "compositionUpdatePolicy", FieldName: "CompositionUpdatePolicy", GoType: "string"},
+			{Name: "size", FieldName: "Size", GoType: "string"},
+		}
+	}
+
+	tests := []struct {
+		name         string
+		properties   []*Property
+		crossplaneV2 bool
+	}{
+		{
+			name:       "crossplane v1",
+			properties: selection(),
+		},
+		{
+			name:         "crossplane v2",
+			properties:   []*Property{{Name: "crossplane", FieldName: "Crossplane", GoType: "struct", Properties: selection()}, {Name: "size", FieldName: "Size", GoType: "string"}},
+			crossplaneV2: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields := compositionFields(tt.properties, tt.crossplaneV2)
+
+			want := []string{"CompositionRef", "CompositionSelector", "CompositionUpdatePolicy"}
+			if strings.Join(fields, ",") != strings.Join(want, ",") {
+				t.Errorf("compositionFields() = %v, want %v", fields, want)
+			}
+
+			properties := tt.properties
+			if tt.crossplaneV2 {
+				properties = properties[0].Properties
+			}
+			for _, prop := range properties {
+				switch prop.Name {
+				case "compositionUpdatePolicy":
+					// Primitives are nullable, so an unset policy is not sent
+					if prop.GoType != "*string" || !prop.Nullable {
+						t.Errorf("%s = %s nullable %v, want a nullable *string", prop.Name, prop.GoType, prop.Nullable)
+					}
+				default:
+					if strings.HasPrefix(prop.GoType, "*") || prop.Nullable {
+						t.Errorf("%s = %s nullable %v, want it unchanged", prop.Name, prop.GoType, prop.Nullable)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestCrossplanePreset(t *testing.T) {
+	v2Schema := &apiextensionsv1.JSONSchemaProps{
+		Properties: map[string]apiextensionsv1.JSONSchemaProps{
+			"spec": {Properties: map[string]apiextensionsv1.JSONSchemaProps{
+				"crossplane": {Properties: map[string]apiextensionsv1.JSONSchemaProps{
+					"compositionRef": {},
+					"resourceRefs":   {},
+				}},
+			}},
+		},
+	}
+
+	tests := []struct {
+		name                 string
+		crossplaneV2         bool
+		compositionSelection bool
+		wantIgnored          []string
+		wantKept             []string
+	}{
+		{
+			name:        "without composition selection",
+			wantIgnored: []string{"/spec/compositionRef", "/spec/compositionUpdatePolicy", "/spec/crossplane"},
+		},
+		{
+			name:                 "crossplane v1 composition selection",
+			compositionSelection: true,
+			wantIgnored:          []string{"/spec/resourceRef", "/spec/crossplane"},
+			wantKept:             []string{"/spec/compositionRef", "/spec/compositionUpdatePolicy"},
+		},
+		{
+			name:                 "crossplane v2 composition selection",
+			crossplaneV2:         true,
+			compositionSelection: true,
+			wantIgnored:          []string{"/spec/crossplane/resourceRefs"},
+			wantKept:             []string{"/spec/crossplane", "/spec/crossplane/compositionRef"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			preset := crossplanePreset(v2Schema, tt.crossplaneV2, tt.compositionSelection)
+
+			for _, path := range tt.wantIgnored {
+				if !slices.Contains(preset, path) {
+					t.Errorf("crossplanePreset() doesn't ignore %s", path)
+				}
+			}
+			for _, path := range tt.wantKept {
+				if slices.Contains(preset, path) {
+					t.Errorf("crossplanePreset() ignores %s", path)
+				}
+			}
+		})
+	}
+}
+
+func TestCRDPropertiesStatusIsComputedOnly(t *testing.T) {
+	status := &apiextensionsv1.JSONSchemaProps{
+		Type:     "object",
+		Required: []string{"phase", "conditions"},
+		Properties: map[string]apiextensionsv1.JSONSchemaProps{
+			"phase": {Type: "string", Enum: []apiextensionsv1.JSON{{Raw: []byte(`"Ready"`)}}},
+			"conditions": {Type: "array", Items: &apiextensionsv1.JSONSchemaPropsOrArray{Schema: &apiextensionsv1.JSONSchemaProps{
+				Type:     "object",
+				Required: []string{"lastTransitionTime"},
+				Properties: map[string]apiextensionsv1.JSONSchemaProps{
+					"lastTransitionTime": {Type: "string", Format: "date-time"},
+				},
+			}}},
+		},
+	}
+
+	var additionalImports AdditionalImports
+	properties, err := crdProperties(status, &additionalImports, true)
+	if err != nil {
+		t.Fatalf("crdProperties() error = %v", err)
+	}
+
+	var check func(properties []*Property)
+	check = func(properties []*Property) {
+		for _, prop := range properties {
+			if prop.Required || prop.Optional || !prop.Computed {
+				t.Errorf("%s: required %v, optional %v, computed %v, want computed only", prop.Name, prop.Required, prop.Optional, prop.Computed)
+			}
+			if len(prop.Validators) > 0 {
+				t.Errorf("%s: validators %v, want none", prop.Name, prop.Validators)
+			}
+			check(prop.Properties)
+		}
+	}
+	check(properties)
+
+	if additionalImports.ValidatorString {
+		t.Error("the string validators are imported")
+	}
+}
+
+func TestCRDPropertiesSpecFormatValidators(t *testing.T) {
+	spec := &apiextensionsv1.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]apiextensionsv1.JSONSchemaProps{
+			"data":     {Type: "string", Format: "byte"},
+			"deadline": {Type: "string", Format: "date-time"},
+		},
+	}
+
+	properties, err := crdProperties(spec, &AdditionalImports{}, false)
+	if err != nil {
+		t.Fatalf("crdProperties() error = %v", err)
+	}
+
+	want := map[string]string{"data": "common.Base64Validator()", "deadline": "common.DateTimeValidator()"}
+	for _, prop := range properties {
+		if len(prop.Validators) != 1 || prop.Validators[0] != want[prop.Name] {
+			t.Errorf("%s: validators %v, want [%s]", prop.Name, prop.Validators, want[prop.Name])
+		}
+	}
+}
@@ -20,18 +20,66 @@ var crdTemplates embed.FS
 //go:embed templates/schema_attribute.go.tmpl
 var resourceTmplates embed.FS
 
+//go:embed templates/datasource.go.tmpl
+//go:embed templates/datasource_attribute.go.tmpl
+var dataSourceTemplates embed.FS
+
+//go:embed templates/list_datasource.go.tmpl
+//go:embed templates/datasource_attribute.go.tmpl
+var listDataSourceTemplates embed.FS
+
 //go:embed templates/resources.go.tmpl
 var resourcesTemplate embed.FS
 
+//go:embed templates/datasources.go.tmpl
+var dataSourcesTemplate embed.FS
+
 //go:embed templates/main.go.tmpl
 var mainTemplate embed.FS
 
 //go:embed templates/provider.go.tmpl
 var providerTemplate embed.FS
 
+//go:embed templates/provider_config.go.tmpl
+var providerConfigTemplate embed.FS
+
 //go:embed templates/resource_data.go.tmpl
 var resourceDataTemplate embed.FS
 
+//go:embed templates/metadata.go.tmpl
+//go:embed templates/readiness.go.tmpl
+//go:embed templates/connection.go.tmpl
+//go:embed templates/crossplane.go.tmpl
+//go:embed templates/describe.go.tmpl
+//go:embed templates/composition.go.tmpl
+//go:embed templates/watch.go.tmpl
+//go:embed templates/cache.go.tmpl
+//go:embed templates/retry.go.tmpl
+//go:embed templates/apply.go.tmpl
+//go:embed templates/warnings.go.tmpl
+//go:embed templates/validators.go.tmpl
+var commonTemplates embed.FS
+
+// commonFiles are the files of the common package shared by the resources and data sources.
+// They don't depend on the configuration.
+var commonFiles = []struct {
+	template string
+	file     string
+}{
+	{"templates/metadata.go.tmpl", "metadata.go"},
+	{"templates/readiness.go.tmpl", "readiness.go"},
+	{"templates/connection.go.tmpl", "connection.go"},
+	{"templates/crossplane.go.tmpl", "crossplane.go"},
+	{"templates/describe.go.tmpl", "describe.go"},
+	{"templates/composition.go.tmpl", "composition.go"},
+	{"templates/watch.go.tmpl", "watch.go"},
+	{"templates/cache.go.tmpl", "cache.go"},
+	{"templates/retry.go.tmpl", "retry.go"},
+	{"templates/apply.go.tmpl", "apply.go"},
+	{"templates/warnings.go.tmpl", "warnings.go"},
+	{"templates/validators.go.tmpl", "validators.go"},
+}
+
 type Generator struct {
 	config *config.Config
 }
@@ -53,6 +101,11 @@ func (g *Generator) Generate() error {
 		return fmt.Errorf("generate provider resources method: %w", err)
 	}
 
+	err = g.generateProviderDataSources(packages)
+	if err != nil {
+		return fmt.Errorf("generate provider data sources method: %w", err)
+	}
+
 	err = g.generateMain()
 	if err != nil {
 		return fmt.Errorf("generate main: %w", err)
@@ -63,11 +116,21 @@ func (g *Generator) Generate() error {
 		return fmt.Errorf("generate provider: %w", err)
 	}
 
+	err = g.generateProviderConfig()
+	if err != nil {
+		return fmt.Errorf("generate provider config: %w", err)
+	}
+
 	err = g.generateResourceData()
 	if err != nil {
 		return fmt.Errorf("generate resource data: %w", err)
 	}
 
+	err = g.generateCommon()
+	if err != nil {
+		return fmt.Errorf("generate common: %w", err)
+	}
+
 	return nil
 }
 
@@ -82,7 +145,22 @@ func (g *Generator) generateResources() ([]string, error) {
 		return nil, fmt.Errorf("get resource template: %w", err)
 	}
 
+	dataSourceTmpl, err := template.ParseFS(dataSourceTemplates, "templates/datasource.go.tmpl", "templates/datasource_attribute.go.tmpl")
+	if err != nil {
+		return nil, fmt.Errorf("get data source template: %w", err)
+	}
+
+	listDataSourceTmpl, err := template.ParseFS(listDataSourceTemplates, "templates/list_datasource.go.tmpl", "templates/datasource_attribute.go.tmpl")
+	if err != nil {
+		return nil, fmt.Errorf("get list data source template: %w", err)
+	}
+
 	var packages []string
+	crdNames := map[string]bool{}
+	// Terraform type names of the resources and data sources mapped to what they are generated for.
+	// Resources and data sources have separate type names, but the list data sources share them with the data sources.
+	resourceTypes := map[string]string{}
+	dataSourceTypes := map[string]string{}
 
 	// generate code for each schema from each template
 	err = filepath.WalkDir(g.config.SchemasDir, func(schemaPath string, d os.DirEntry, err error) error {
@@ -96,36 +174,80 @@ func (g *Generator) generateResources() ([]string, error) {
 
 		slog.Info("generating code for schema", "path", schemaPath)
 
-		data, err := parseSchema(schemaPath)
+		dataList, err := parseSchema(schemaPath, g.config)
 		if err != nil {
 			return fmt.Errorf("parse schema: %w", err)
 		}
 
-		data.ModuleName = g.config.ModuleName
-
-		outDir := filepath.Join(g.config.OutputDir, "/internal/provider", data.PackageName)
-
-		err = generateCode(crdTmpl, data, outDir, "crd.go")
-		if err != nil {
-			return fmt.Errorf("generate CRD code: %w", err)
-		}
-
-		err = generateCode(resourceTmpl, data, outDir, "resource.go")
-		if err != nil {
-			return fmt.Errorf("generate Terraform resource code: %w", err)
+		for _, data := range dataList {
+			data.ModuleName = g.config.ModuleName
+			crdNames[data.CRDName] = true
+
+			err = addTypeName(resourceTypes, "resource", data.ResourceName, data.CRDName)
+			if err != nil {
+				return err
+			}
+			err = addTypeName(dataSourceTypes, "data source", data.ResourceName, data.CRDName)
+			if err != nil {
+				return err
+			}
+			err = addTypeName(dataSourceTypes, "list data source", data.PluralName, data.CRDName)
+			if err != nil {
+				return err
+			}
+
+			outDir := filepath.Join(g.config.OutputDir, "/internal/provider", data.PackageName)
+
+			err = generateCode(crdTmpl, data, outDir, "crd.go")
+			if err != nil {
+				return fmt.Errorf("generate CRD code: %w", err)
+			}
+
+			err = generateCode(resourceTmpl, data, outDir, "resource.go")
+			if err != nil {
+				return fmt.Errorf("generate Terraform resource code: %w", err)
+			}
+
+			err = generateCode(dataSourceTmpl, data, outDir, "datasource.go")
+			if err != nil {
+				return fmt.Errorf("generate Terraform data source code: %w", err)
+			}
+
+			err = generateCode(listDataSourceTmpl, data, outDir, "list_datasource.go")
+			if err != nil {
+				return fmt.Errorf("generate Terraform list data source code: %w", err)
+			}
+
+			packages = append(packages, data.PackageName)
 		}
 
-		packages = append(packages, data.PackageName)
-
 		return nil
 	})
 	if err != nil {
 		return nil, fmt.Errorf("generating CRD types: %w", err)
 	}
 
+	// A typo in the CRD name would silently fall back to the defaults
+	for crdName := range g.config.Resources {
+		if !crdNames[crdName] {
+			return nil, fmt.Errorf("resource configuration for unknown CRD %s", crdName)
+		}
+	}
+
 	return packages, nil
 }
 
+// addTypeName records the Terraform type name of the CRD. It fails if the name is taken by another CRD or,
+// e.g. for a kind whose plural is the same as the kind, by the other data source of the CRD.
+func addTypeName(typeNames map[string]string, typ, name, crdName string) error {
+	if owner, ok := typeNames[name]; ok {
+		return fmt.Errorf("%s type name %s of CRD %s is already used by the %s", typ, name, crdName, owner)
+	}
+	typeNames[name] = typ + " of CRD " + crdName
+
+	return nil
+}
+
 func (g *Generator) generateProviderResources(packages []string) error {
 	tmpl, err := template.ParseFS(resourcesTemplate, "templates/resources.go.tmpl")
 	if err != nil {
@@ -150,6 +272,30 @@ func (g *Generator) generateProviderResources(packages []string) error {
 	return nil
 }
 
+func (g *Generator) generateProviderDataSources(packages []string) error {
+	tmpl, err := template.ParseFS(dataSourcesTemplate, "templates/datasources.go.tmpl")
+	if err != nil {
+		return fmt.Errorf("get provider data sources template: %w", err)
+	}
+
+	outDir := filepath.Join(g.config.OutputDir, "internal/provider")
+
+	data := struct {
+		Packages   []string
+		ModuleName string
+	}{
+		Packages:   packages,
+		ModuleName: g.config.ModuleName,
+	}
+
+	err = generateCode(tmpl, data, outDir, "datasources.go")
+	if err != nil {
+		return fmt.Errorf("generate provider data sources method code: %w", err)
+	}
+
+	return nil
+}
+
 func (g *Generator) generateMain() error {
 	tmpl, err := template.ParseFS(mainTemplate, "templates/main.go.tmpl")
 	if err != nil {
@@ -182,6 +328,22 @@ func (g *Generator) generateProvider() error {
 	return nil
 }
 
+func (g *Generator) generateProviderConfig() error {
+	tmpl, err := template.ParseFS(providerConfigTemplate, "templates/provider_config.go.tmpl")
+	if err != nil {
+		return fmt.Errorf("get provider config template: %w", err)
+	}
+
+	outDir := filepath.Join(g.config.OutputDir, "internal/provider")
+
+	err = generateCode(tmpl, g.config, outDir, "provider_config.go")
+	if err != nil {
+		return fmt.Errorf("generate provider config code: %w", err)
+	}
+
+	return nil
+}
+
 func (g *Generator) generateResourceData() error {
 	tmpl, err := template.ParseFS(resourceDataTemplate, "templates/resource_data.go.tmpl")
 	if err != nil {
@@ -198,6 +360,24 @@ func (g *Generator) generateResourceData() error {
 	return nil
 }
 
+func (g *Generator) generateCommon() error {
+	outDir := filepath.Join(g.config.OutputDir, "internal/provider/common")
+
+	for _, common := range commonFiles {
+		tmpl, err := template.ParseFS(commonTemplates, common.template)
+		if err != nil {
+			return fmt.Errorf("get %s template: %w", common.template, err)
+		}
+
+		err = generateCode(tmpl, nil, outDir, common.file)
+		if err != nil {
+			return fmt.Errorf("generate %s code: %w", common.file, err)
+		}
+	}
+
+	return nil
+}
+
 func generateCode(tmpl *template.Template, data any, outDir, outFileName string) error {
 	err := os.MkdirAll(outDir, os.ModePerm)
 	if err != nil {
@@ -0,0 +1,111 @@
+package generator
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/vvbogdanov87/tfpgen/pkg/config"
+)
+
+const (
+	// testSchemasDir contains the schemas of the provider used by the e2e tests.
+	testSchemasDir = "../../tests/terraform-provider-crd/schemas"
+	// testCommonDir contains the tests of the generated common package.
+	testCommonDir = "testdata"
+)
+
+// TestGenerateBuilds generates the provider from the e2e test schemas, checks that it builds
+// and runs the tests of the common package.
+// It downloads the provider dependencies, so it is skipped in the short mode.
+func TestGenerateBuilds(t *testing.T) {
+	if testing.Short() {
+		t.Skip("generating and building a provider is skipped in the short mode")
+	}
+
+	tests := []struct {
+		name   string
+		config string
+	}{
+		{
+			name:   "crossplane",
+			config: "mode: crossplane\n",
+		},
+		{
+			name:   "plain",
+			config: "mode: plain\n",
+		},
+		{
+			name:   "crossplane fields kept",
+			config: "mode: crossplane\nignoreCrossplaneFields: false\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			copyFiles(t, testSchemasDir, filepath.Join(dir, "schemas"))
+
+			configFile := filepath.Join(dir, "tfpgen.yaml")
+			content := `name: crd
+address: registry.terraform.io/vvbogdanov87/crd
+moduleName: github.com/vvbogdanov87/terraform-provider-crd
+schemasDir: schemas
+outputDir: .
+` + tt.config
+			if err := os.WriteFile(configFile, []byte(content), 0o644); err != nil {
+				t.Fatalf("write config: %v", err)
+			}
+
+			cfg, err := config.NewConfig(configFile)
+			if err != nil {
+				t.Fatalf("NewConfig() error = %v", err)
+			}
+			if err := NewGenerator(cfg).Generate(); err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+
+			// The tests are copied before tidying, so their dependencies are downloaded too
+			copyFiles(t, testCommonDir, filepath.Join(dir, "internal/provider/common"))
+
+			goCommand(t, dir, "mod", "init", cfg.ModuleName)
+			goCommand(t, dir, "mod", "tidy")
+			goCommand(t, dir, "vet", "./...")
+			goCommand(t, dir, "test", "./internal/provider/common")
+		})
+	}
+}
+
+func copyFiles(t *testing.T, src, dst string) {
+	t.Helper()
+
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		t.Fatalf("read %s: %v", src, err)
+	}
+
+	if err := os.MkdirAll(dst, 0o755); err != nil {
+		t.Fatalf("create %s: %v", dst, err)
+	}
+
+	for _, entry := range entries {
+		content, err := os.ReadFile(filepath.Join(src, entry.Name()))
+		if err != nil {
+			t.Fatalf("read file: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dst, entry.Name()), content, 0o644); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+	}
+}
+
+func goCommand(t *testing.T, dir string, args ...string) {
+	t.Helper()
+
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("go %v: %v\n%s", args, err, output)
+	}
+}
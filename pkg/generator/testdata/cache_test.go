@@ -0,0 +1,61 @@
+package common
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sSchema "k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestReadCacheGetWaitsUntilContextIsDone(t *testing.T) {
+	gvr := k8sSchema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "buckets"}
+
+	bucket := &unstructured.Unstructured{}
+	bucket.SetAPIVersion("example.com/v1")
+	bucket.SetKind("Bucket")
+	bucket.SetNamespace("default")
+	bucket.SetName("bucket")
+
+	client := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[k8sSchema.GroupVersionResource]string{gvr: "BucketList"}, bucket)
+
+	// The list is slow until it is released
+	release := make(chan struct{})
+	client.PrependReactor("list", "buckets", func(k8stesting.Action) (bool, runtime.Object, error) {
+		<-release
+		return false, nil, nil
+	})
+
+	cache := NewReadCache(context.Background())
+	resourceClient := client.Resource(gvr).Namespace("default")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, ok := cache.Get(ctx, resourceClient, gvr, "default", "bucket"); ok {
+		t.Fatal("Get() found the object before the list is made")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("Get() returned after %s, want it to return when the context is done", elapsed)
+	}
+
+	// The list made in the background serves the next reads
+	close(release)
+
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	obj, ok := cache.Get(ctx, resourceClient, gvr, "default", "bucket")
+	if !ok || obj.GetName() != "bucket" {
+		t.Fatalf("Get() = %v, %t, want the bucket", obj, ok)
+	}
+	if _, ok := cache.Get(ctx, resourceClient, gvr, "default", "missing"); ok {
+		t.Fatal("Get() found a missing object")
+	}
+}
@@ -0,0 +1,73 @@
+package common
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func stringMap(values map[string]string) types.Map {
+	elements := map[string]attr.Value{}
+	for key, value := range values {
+		elements[key] = types.StringValue(value)
+	}
+
+	return types.MapValueMust(types.StringType, elements)
+}
+
+func TestMergedMetadata(t *testing.T) {
+	tests := []struct {
+		name       string
+		defaults   map[string]string
+		configured types.Map
+		want       types.Map
+	}{
+		{
+			name:       "unknown",
+			defaults:   map[string]string{"team": "platform"},
+			configured: types.MapUnknown(types.StringType),
+			want:       types.MapUnknown(types.StringType),
+		},
+		{
+			name:       "null without defaults",
+			configured: types.MapNull(types.StringType),
+			want:       types.MapNull(types.StringType),
+		},
+		{
+			name:       "null with defaults",
+			defaults:   map[string]string{"team": "platform"},
+			configured: types.MapNull(types.StringType),
+			want:       stringMap(map[string]string{"team": "platform"}),
+		},
+		{
+			name:       "configured without defaults",
+			configured: stringMap(map[string]string{"app": "bucket"}),
+			want:       stringMap(map[string]string{"app": "bucket"}),
+		},
+		{
+			name:       "configured values take precedence",
+			defaults:   map[string]string{"team": "platform", "env": "dev"},
+			configured: stringMap(map[string]string{"app": "bucket", "env": "prod"}),
+			want:       stringMap(map[string]string{"team": "platform", "app": "bucket", "env": "prod"}),
+		},
+		{
+			name:       "empty map without defaults",
+			configured: stringMap(map[string]string{}),
+			want:       stringMap(map[string]string{}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, diags := MergedMetadata(context.Background(), tt.defaults, tt.configured)
+			if diags.HasError() {
+				t.Fatalf("MergedMetadata() diagnostics = %v", diags)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("MergedMetadata() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package common
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseReadiness(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   map[string]any
+		want    *Readiness
+		wantErr string
+	}{
+		{
+			name:  "empty object",
+			value: map[string]any{},
+		},
+		{
+			name: "default condition status",
+			value: map[string]any{
+				"conditions": []any{
+					map[string]any{"type": "Available"},
+					map[string]any{"type": "Degraded", "status": "False"},
+				},
+			},
+			want: &Readiness{Conditions: []ReadinessCondition{
+				{Type: "Available", Status: "True"},
+				{Type: "Degraded", Status: "False"},
+			}},
+		},
+		{
+			name: "JSON paths with and without braces",
+			value: map[string]any{
+				"json_paths": []any{
+					map[string]any{"path": ".status.phase", "value": "Running"},
+					map[string]any{"path": `{.status.conditions[?(@.type=="Synced")].status}`},
+				},
+				"observed_generation": true,
+			},
+			want: &Readiness{
+				JSONPaths: []ReadinessJSONPath{
+					{Path: ".status.phase", Value: "Running"},
+					{Path: `{.status.conditions[?(@.type=="Synced")].status}`},
+				},
+				ObservedGeneration: true,
+			},
+		},
+		{
+			name:    "unknown attribute",
+			value:   map[string]any{"timeout": "5m"},
+			wantErr: "decode wait object",
+		},
+		{
+			name:    "condition without a type",
+			value:   map[string]any{"conditions": []any{map[string]any{"status": "True"}}},
+			wantErr: "condition 0 doesn't have a type",
+		},
+		{
+			name:    "JSON path without a path",
+			value:   map[string]any{"json_paths": []any{map[string]any{"value": "Running"}}},
+			wantErr: "JSON path 0 doesn't have a path",
+		},
+		{
+			name:    "invalid JSON path",
+			value:   map[string]any{"json_paths": []any{map[string]any{"path": ".status.conditions[?(@.type=="}}},
+			wantErr: "parse JSON path .status.conditions[?(@.type==",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseReadiness(tt.value)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("parseReadiness() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseReadiness() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseReadiness() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
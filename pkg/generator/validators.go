@@ -49,12 +49,12 @@ func getStringValidators(sProp *apiextensionsv1.JSONSchemaProps, additionalImpor
 
 	// Byte format validator
 	if sProp.Format == "byte" {
-		validators = append(validators, "validators.Base64Validator()")
+		validators = append(validators, "common.Base64Validator()")
 	}
 
 	// Datetime format validator
 	if sProp.Format == "date-time" {
-		validators = append(validators, "validators.DateTime64Validator()")
+		validators = append(validators, "common.DateTimeValidator()")
 	}
 
 	return validators
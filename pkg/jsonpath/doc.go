@@ -0,0 +1,21 @@
+/*
+Copyright 2015 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package jsonpath is the JSONPath parser of k8s.io/client-go/util/jsonpath v0.34.1.
+// The generated providers evaluate the readiness JSONPaths with client-go, and tfpgen parses
+// them with the same parser to reject invalid expressions at generation.
+// Only the parser is copied, so tfpgen doesn't depend on client-go.
+package jsonpath
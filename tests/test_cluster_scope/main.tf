@@ -0,0 +1,23 @@
+terraform {
+  required_providers {
+    crd = {
+      source = "registry.terraform.io/vvbogdanov87/crd"
+    }
+  }
+}
+
+provider "crd" {
+  namespace = "default"
+}
+
+# The composite resource is cluster-scoped, so it doesn't have a namespace argument
+resource "crd_xdefault" "example" {
+  name = "cluster-scoped"
+  spec = {
+    prefix = "cluster-scoped"
+  }
+}
+
+output "arn" {
+  value = crd_xdefault.example.status.arn
+}
@@ -0,0 +1,43 @@
+terraform {
+  required_providers {
+    crd = {
+      source = "registry.terraform.io/vvbogdanov87/crd"
+    }
+  }
+}
+
+provider "crd" {
+  namespace = "default"
+}
+
+resource "crd_default" "example" {
+  for_each = toset(["ds-one", "ds-two"])
+
+  name = each.key
+  labels = {
+    "test.prc.com/suite" = "datasources"
+  }
+  spec = {
+    prefix = each.key
+  }
+}
+
+data "crd_default" "one" {
+  name = "ds-one"
+
+  depends_on = [crd_default.example]
+}
+
+data "crd_defaults" "suite" {
+  label_selector = "test.prc.com/suite=datasources"
+
+  depends_on = [crd_default.example]
+}
+
+output "arn" {
+  value = data.crd_default.one.status.arn
+}
+
+output "names" {
+  value = join(",", sort(data.crd_defaults.suite.items[*].name))
+}
@@ -0,0 +1,40 @@
+terraform {
+  required_providers {
+    crd = {
+      source = "registry.terraform.io/vvbogdanov87/crd"
+    }
+  }
+}
+
+provider "crd" {
+  namespace = "default"
+}
+
+variable "claim_deletion_policy" {
+  type    = string
+  default = "Delete"
+}
+
+variable "paused" {
+  type    = bool
+  default = false
+}
+
+# The composed resources of the composite resource are kept when it is destroyed
+resource "crd_xdefault" "orphan" {
+  name            = "orphan"
+  deletion_policy = "Orphan"
+  spec = {
+    prefix = "orphan"
+  }
+}
+
+# Claims can't be orphaned
+resource "crd_default" "claim" {
+  name            = "claim"
+  deletion_policy = var.claim_deletion_policy
+  paused          = var.paused
+  spec = {
+    prefix = "claim"
+  }
+}
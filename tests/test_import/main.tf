@@ -0,0 +1,24 @@
+terraform {
+  required_providers {
+    crd = {
+      source = "registry.terraform.io/vvbogdanov87/crd"
+    }
+  }
+}
+
+provider "crd" {
+  namespace = "default"
+}
+
+import {
+  to = crd_default.example
+  id = "default/imported"
+}
+
+resource "crd_default" "example" {
+  name = "imported"
+  spec = {
+    prefix             = "imported"
+    string_default_one = "ololo"
+  }
+}
@@ -0,0 +1,34 @@
+terraform {
+  required_providers {
+    crd = {
+      source = "registry.terraform.io/vvbogdanov87/crd"
+    }
+  }
+}
+
+provider "crd" {
+  namespace = "default"
+  default_labels = {
+    "cost-center" = "1234"
+    "team"        = "default"
+  }
+}
+
+variable "labels" {
+  type = map(string)
+  default = {
+    "team" = "platform"
+    "tier" = "backend"
+  }
+}
+
+resource "crd_default" "example" {
+  name   = "labels"
+  labels = var.labels
+  annotations = {
+    "prc.com/owner" = "platform@prc.com"
+  }
+  spec = {
+    prefix = "labels"
+  }
+}
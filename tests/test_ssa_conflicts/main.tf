@@ -0,0 +1,30 @@
+terraform {
+  required_providers {
+    crd = {
+      source = "registry.terraform.io/vvbogdanov87/crd"
+    }
+  }
+}
+
+provider "crd" {
+  namespace = "default"
+}
+
+variable "string_default_one" {
+  type    = string
+  default = "terraform"
+}
+
+variable "force_conflicts" {
+  type    = bool
+  default = false
+}
+
+resource "crd_default" "example" {
+  name            = "conflicts"
+  force_conflicts = var.force_conflicts
+  spec = {
+    prefix             = "conflicts"
+    string_default_one = var.string_default_one
+  }
+}
@@ -0,0 +1,37 @@
+terraform {
+  required_providers {
+    crd = {
+      source = "registry.terraform.io/vvbogdanov87/crd"
+    }
+  }
+}
+
+provider "crd" {
+  namespace = "default"
+}
+
+variable "wait" {
+  type    = any
+  default = true
+}
+
+variable "force_conflicts" {
+  type    = bool
+  default = false
+}
+
+resource "crd_default" "example" {
+  timeouts = {
+    create = "1m"
+    update = "3m" # longer than the script timeouts, an update waiting for the controller fails the test
+    delete = "2m"
+    read   = "1m"
+  }
+
+  name            = "noop"
+  wait            = var.wait
+  force_conflicts = var.force_conflicts
+  spec = {
+    prefix = "noop"
+  }
+}